@@ -1,17 +1,21 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/go-co-op/gocron"
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"gorsyncv2/internal/archive"
+	"gorsyncv2/internal/filter"
 )
 
 var (
@@ -19,14 +23,36 @@ var (
 	backupDir      string
 	schedulePeriod time.Duration
 	includePaths   []string
+	excludePaths   []string
+	parallelCopies int
+	reflinkMode    string
+	configPath     string
+	debouncePeriod time.Duration
+	mirrorDeletes  bool
 )
 
 var backupCmd = &cobra.Command{
 	Use:   "backup",
 	Short: "Start the backup and synchronization process",
 	Long: `Start the backup and synchronization process from a source to a destination directory.
-Supports both real-time file watching and scheduled sync operations.`,
+Supports both real-time file watching and scheduled sync operations.
+
+Passing --config instead runs every job declared in that YAML file on its own
+cron schedule; see "gorsync jobs" for their live status.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if configPath != "" {
+			runConfigScheduler(configPath)
+			return
+		}
+		if sourceDir == "" || backupDir == "" {
+			fmt.Println("Error: --source and --destination are required unless --config is set")
+			return
+		}
+		if reflinkMode != "auto" && reflinkMode != "always" && reflinkMode != "never" {
+			fmt.Printf("Error: --reflink must be auto, always, or never, got %q\n", reflinkMode)
+			return
+		}
+
 		if schedulePeriod > 0 {
 			schedule := gocron.NewScheduler(time.Local)
 			schedule.Every(schedulePeriod).Do(runScheduledBackup)
@@ -37,23 +63,35 @@ Supports both real-time file watching and scheduled sync operations.`,
 	},
 	Example: `  gorsync backup --source=/home/user/data --destination=/mnt/backup --interval=1h
   gorsync backup -s /path/to/source -d /path/to/destination
-  gorsync backup -s /path/to/source -d /path/to/destination -i 30m --include=filename1.txt,folder1`,
+  gorsync backup -s /path/to/source -d /path/to/destination --mirror-deletes --debounce=1s
+  gorsync backup -s /path/to/source -d /path/to/destination -i 30m --include=folder1/** --exclude=*.tmp
+  gorsync backup -s /path/to/source -d "tar+gzip+age:///mnt/backup/backup-{date}.tar.gz" -i 24h --encrypt --passphrase-file=/etc/gorsync.key
+  gorsync backup --config=/etc/gorsync.yaml`,
 }
 
 func init() {
 	rootCmd.AddCommand(backupCmd)
-	backupCmd.Flags().StringVarP(&sourceDir, "source", "s", "", "Source directory (required)")
-	backupCmd.Flags().StringVarP(&backupDir, "destination", "d", "", "Backup directory (required)")
+	backupCmd.Flags().StringVarP(&sourceDir, "source", "s", "", "Source directory")
+	backupCmd.Flags().StringVarP(&backupDir, "destination", "d", "", "Backup directory, or an archive URI such as tar+gzip:///path/backup-{date}.tar.gz")
 	backupCmd.Flags().DurationVarP(&schedulePeriod, "interval", "i", 0, "Schedule interval for backups (e.g., 1h, 30m). If omitted, real-time sync will be used.")
-	backupCmd.Flags().StringArrayVarP(&includePaths, "include", "c", nil, "Comma-separated list of file or folder names to sync (optional)")
-
-	backupCmd.MarkFlagRequired("source")
-	backupCmd.MarkFlagRequired("destination")
+	backupCmd.Flags().StringArrayVarP(&includePaths, "include", "c", nil, "Gitignore-style pattern (repeatable) a path must match to be synced/watched; if omitted, everything is included by default")
+	backupCmd.Flags().StringArrayVar(&excludePaths, "exclude", nil, "Gitignore-style pattern (repeatable) to exclude from an otherwise included tree; \".gorsyncignore\" files found while walking apply the same way")
+	backupCmd.Flags().IntVarP(&parallelCopies, "parallel", "p", 4, "Number of concurrent file-copy workers to use for scheduled syncs")
+	backupCmd.Flags().StringVar(&reflinkMode, "reflink", "auto", "Copy-on-write reflink behavior: auto, always, or never")
+	backupCmd.Flags().StringVar(&configPath, "config", "", "Path to a YAML file declaring multiple named, independently cron-scheduled backup jobs; overrides --source/--destination/--interval")
+	backupCmd.Flags().DurationVar(&debouncePeriod, "debounce", 500*time.Millisecond, "Debounce window for real-time sync: coalesces a burst of fsnotify events on the same path into one copy")
+	backupCmd.Flags().BoolVar(&mirrorDeletes, "mirror-deletes", false, "In real-time sync, also delete a file/directory from the destination when it's removed or renamed away from the source")
 }
 
 func runScheduledBackup() {
 	fmt.Printf("Starting scheduled backup at %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	err := syncDirectories(sourceDir, backupDir, includePaths)
+
+	var err error
+	if archive.IsArchiveURI(backupDir) {
+		err = runArchiveBackup()
+	} else {
+		err = syncDirectories(sourceDir, backupDir, includePaths, excludePaths, parallelCopies, reflinkMode)
+	}
 	if err != nil {
 		fmt.Printf("Error during scheduled backup: %v\n", err)
 	} else {
@@ -61,137 +99,177 @@ func runScheduledBackup() {
 	}
 }
 
-func runRealTimeSync() {
-	fmt.Printf("Starting real-time sync at %s\n", time.Now().Format("2006-01-02 15:04:05"))
+// relSlashOrRoot turns the "." filepath.Rel produces for a directory's own
+// root into the "" base LoadIgnoreFile expects for that same directory.
+func relSlashOrRoot(relPath string) string {
+	if relPath == "." {
+		return ""
+	}
+	return relPath
+}
 
-	watcher, err := fsnotify.NewWatcher()
+// copyJob describes a single file copy to be performed by a worker.
+type copyJob struct {
+	src  string
+	dst  string
+	mode os.FileMode
+}
+
+// syncDirectories mirrors src into dst, dispatching file copies to a fixed-size
+// pool of workers. Directories are always created synchronously by the walker
+// so that no worker ever races on creating a parent directory.
+func syncDirectories(src, dst string, includes, excludes []string, parallel int, reflink string) error {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	totalBytes, err := accountSyncSize(src, dst, includes, excludes)
 	if err != nil {
-		fmt.Println("Error creating watcher:", err)
-		return
+		return err
 	}
-	defer watcher.Close()
 
-	var pathsToWatch []string
-	if len(includePaths) > 0 {
-		for _, includePath := range includePaths {
-			absPath, err := filepath.Abs(filepath.Join(sourceDir, includePath))
+	bar := progressbar.NewOptions64(
+		totalBytes,
+		progressbar.OptionSetDescription("Syncing"),
+		progressbar.OptionSetWidth(20),
+		progressbar.OptionSetPredictTime(true),
+		progressbar.OptionThrottle(100*time.Millisecond),
+		progressbar.OptionShowBytes(true),
+	)
+
+	jobs := make(chan copyJob)
+	results := make(chan error)
+
+	g, _ := errgroup.WithContext(context.Background())
+	for i := 0; i < parallel; i++ {
+		g.Go(func() error {
+			for job := range jobs {
+				results <- copyJobToBar(job, bar, reflink)
+			}
+			return nil
+		})
+	}
+
+	g.Go(func() error {
+		defer close(jobs)
+		matcher := filter.New(src, includes, excludes)
+		return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
-				fmt.Println("Error getting absolute path:", err)
-				return
+				return err
 			}
-			pathsToWatch = append(pathsToWatch, absPath)
-		}
-	} else {
-		err = filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+
+			relPath, err := filepath.Rel(src, path)
 			if err != nil {
 				return err
 			}
+			relPath = filepath.ToSlash(relPath)
+
+			if relPath != "." && !matcher.Match(relPath, info.IsDir()) {
+				if !info.IsDir() {
+					return nil
+				}
+				if !matcher.CouldContainMatch(relPath) {
+					return filepath.SkipDir
+				}
+				// relPath itself doesn't match, but a descendant might
+				// (e.g. --include=a/b/c/** with relPath "a"), so keep
+				// walking and still materialize the directory below.
+			}
+
+			destPath := filepath.Join(dst, relPath)
+
 			if info.IsDir() {
-				err = watcher.Add(path)
-				if err != nil {
+				if err := matcher.LoadIgnoreFile(relSlashOrRoot(relPath)); err != nil {
 					return err
 				}
+				if _, err := os.Stat(destPath); os.IsNotExist(err) {
+					if err := os.MkdirAll(destPath, info.Mode()); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			if shouldCopyFile(path, destPath, info) {
+				jobs <- copyJob{src: path, dst: destPath, mode: info.Mode()}
 			}
+
 			return nil
 		})
+	})
+
+	waitErr := make(chan error, 1)
+	go func() {
+		err := g.Wait()
+		close(results)
+		waitErr <- err
+	}()
+
+	var errs []error
+	for err := range results {
 		if err != nil {
-			fmt.Println("Error walking source directory:", err)
-			return
+			errs = append(errs, err)
 		}
 	}
+	if err := <-waitErr; err != nil {
+		errs = append(errs, err)
+	}
 
-	done := make(chan bool)
-	go func() {
-		for {
-			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					return
-				}
-				if event.Op&fsnotify.Write == fsnotify.Write || event.Op&fsnotify.Create == fsnotify.Create {
-					if shouldWatch(event.Name, includePaths) {
-						fmt.Println("Detected modification:", event.Name)
-						relPath, err := filepath.Rel(sourceDir, event.Name)
-						if err != nil {
-							fmt.Println("Error getting relative path:", err)
-							continue
-						}
-						destPath := filepath.Join(backupDir, relPath)
-						copyFileWithProgress(event.Name, destPath)
-					}
-				}
-			case err, ok := <-watcher.Errors:
-				if !ok {
-					return
-				}
-				fmt.Println("Error:", err)
-			}
-		}
-	}()
+	bar.Finish()
+	fmt.Println()
 
-	<-done
+	return errors.Join(errs...)
 }
 
-func syncDirectories(src, dst string, includes []string) error {
-	includeMap := make(map[string]bool)
-	for _, includePath := range includes {
-		includeMap[includePath] = true
-	}
-
-	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+// accountSyncSize pre-walks src to total the bytes that will actually be
+// copied, so the aggregate progress bar has a known size before the
+// concurrent copy starts.
+func accountSyncSize(src, dst string, includes, excludes []string) (int64, error) {
+	var total int64
+	matcher := filter.New(src, includes, excludes)
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if len(includeMap) > 0 && !matchesAnyInclude(path, includes) {
-			return nil
-		}
-
 		relPath, err := filepath.Rel(src, path)
 		if err != nil {
 			return err
 		}
+		relPath = filepath.ToSlash(relPath)
 
-		destPath := filepath.Join(dst, relPath)
-
-		if info.IsDir() {
-			if _, err := os.Stat(destPath); os.IsNotExist(err) {
-				if err := os.MkdirAll(destPath, info.Mode()); err != nil {
-					return err
-				}
+		if relPath != "." && !matcher.Match(relPath, info.IsDir()) {
+			if !info.IsDir() {
+				return nil
 			}
-		} else {
-			if shouldCopyFile(path, destPath, info) {
-				err := copyFileWithProgress(path, destPath)
-				if err != nil {
-					return err
-				}
+			if !matcher.CouldContainMatch(relPath) {
+				return filepath.SkipDir
 			}
 		}
 
-		return nil
-	})
-}
+		if info.IsDir() {
+			return matcher.LoadIgnoreFile(relSlashOrRoot(relPath))
+		}
 
-func matchesAnyInclude(path string, includes []string) bool {
-	for _, include := range includes {
-		if strings.Contains(filepath.Base(path), include) || strings.Contains(filepath.Dir(path), include) {
-			return true
+		destPath := filepath.Join(dst, relPath)
+		if shouldCopyFile(path, destPath, info) {
+			total += info.Size()
 		}
-	}
-	return false
+		return nil
+	})
+	return total, err
 }
 
-func shouldWatch(path string, includes []string) bool {
-	if len(includes) == 0 {
-		return true
+// copyJobToBar performs a single copy job, reporting progress onto a shared
+// aggregate bar rather than a per-file one.
+func copyJobToBar(job copyJob, bar *progressbar.ProgressBar, reflink string) error {
+	if _, err := copyFileData(job.src, job.dst, bar, reflink); err != nil {
+		return fmt.Errorf("copying %s: %w", job.src, err)
 	}
-	for _, include := range includes {
-		if strings.Contains(filepath.Base(path), include) || strings.Contains(filepath.Dir(path), include) {
-			return true
-		}
+	if err := os.Chmod(job.dst, job.mode); err != nil {
+		return fmt.Errorf("chmod %s: %w", job.dst, err)
 	}
-	return false
+	return nil
 }
 
 func shouldCopyFile(src, dst string, srcInfo os.FileInfo) bool {
@@ -206,19 +284,7 @@ func shouldCopyFile(src, dst string, srcInfo os.FileInfo) bool {
 }
 
 func copyFileWithProgress(src, dst string) error {
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer srcFile.Close()
-
-	dstFile, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer dstFile.Close()
-
-	srcInfo, err := srcFile.Stat()
+	srcInfo, err := os.Stat(src)
 	if err != nil {
 		return err
 	}
@@ -236,8 +302,7 @@ func copyFileWithProgress(src, dst string) error {
 	// Initialize variables for time calculation
 	startTime := time.Now()
 
-	// Copy file and update progress
-	_, err = io.Copy(io.MultiWriter(dstFile, bar), srcFile)
+	reflinked, err := copyFileData(src, dst, bar, reflinkMode)
 	if err != nil {
 		return err
 	}
@@ -245,17 +310,61 @@ func copyFileWithProgress(src, dst string) error {
 	// Finalize the progress bar and print final time taken
 	elapsedTime := time.Since(startTime)
 	formattedElapsedTime := fmt.Sprintf("%.2f seconds", elapsedTime.Seconds())
+	suffix := ""
+	if reflinked {
+		suffix = " (reflinked)"
+	}
 
 	// Move cursor to the beginning of the line and print the final progress
-	fmt.Printf("\rCopying %s 100%% |%s| (%s) %s\n", filepath.Base(src), bar.String(), formatBytesPerSecond(srcInfo.Size(), elapsedTime), formattedElapsedTime)
+	fmt.Printf("\rCopying %s 100%% |%s| (%s) %s%s\n", filepath.Base(src), bar.String(), formatBytesPerSecond(srcInfo.Size(), elapsedTime), formattedElapsedTime, suffix)
+
+	return os.Chmod(dst, srcInfo.Mode())
+}
 
-	// Ensure file permissions are copied
-	err = dstFile.Sync()
+// copyFileData copies src to dst, mirroring every written byte onto bar so
+// callers can track progress either per-file or on a shared aggregate bar.
+// When --reflink!=never, the kernel-side fast path of tryReflink (a FICLONE
+// clone, or a copy_file_range copy where the clone is refused) is tried
+// first and bar is credited with the whole file's size in one step; Add64,
+// not Set64, so this adds to whatever the rest of the aggregate bar has
+// already reported instead of resetting it. reflinked reports whether that
+// fast path was used, and does not distinguish a clone from a
+// copy_file_range copy. --reflink=always returns an error only when neither
+// of the two is usable; a copy_file_range copy satisfies it.
+func copyFileData(src, dst string, bar *progressbar.ProgressBar, reflink string) (reflinked bool, err error) {
+	srcFile, err := os.Open(src)
 	if err != nil {
-		return err
+		return false, err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.Create(dst)
+	if err != nil {
+		return false, err
 	}
+	defer dstFile.Close()
 
-	return os.Chmod(dst, srcInfo.Mode())
+	if reflink != "never" {
+		ok, err := tryReflink(srcFile, dstFile)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			if info, statErr := srcFile.Stat(); statErr == nil {
+				bar.Add64(info.Size())
+			}
+			return true, dstFile.Sync()
+		}
+		if reflink == "always" {
+			return false, fmt.Errorf("reflink copy of %s refused by filesystem", src)
+		}
+	}
+
+	if _, err := io.Copy(io.MultiWriter(dstFile, bar), srcFile); err != nil {
+		return false, err
+	}
+
+	return false, dstFile.Sync()
 }
 
 func formatBytesPerSecond(size int64, elapsed time.Duration) string {
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"gorsyncv2/internal/filter"
+)
+
+var checkIgnoreCmd = &cobra.Command{
+	Use:   "check-ignore <path>",
+	Short: "Report which --include/--exclude/.gorsyncignore rule matches a path",
+	Long: `Report which --include, --exclude, or .gorsyncignore rule would decide a
+path's fate during "gorsync backup", the same way "git check-ignore -v"
+reports which .gitignore line matched. Useful for debugging why a file is or
+isn't being synced.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCheckIgnore(args[0]); err != nil {
+			fmt.Println("Error:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkIgnoreCmd)
+	checkIgnoreCmd.Flags().StringVarP(&sourceDir, "source", "s", "", "Source directory the path is evaluated under (required)")
+	checkIgnoreCmd.Flags().StringArrayVarP(&includePaths, "include", "c", nil, "Same --include patterns \"gorsync backup\" would use")
+	checkIgnoreCmd.Flags().StringArrayVar(&excludePaths, "exclude", nil, "Same --exclude patterns \"gorsync backup\" would use")
+	checkIgnoreCmd.MarkFlagRequired("source")
+}
+
+func runCheckIgnore(target string) error {
+	absSource, err := filepath.Abs(sourceDir)
+	if err != nil {
+		return err
+	}
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		return err
+	}
+
+	relPath, err := filepath.Rel(absSource, absTarget)
+	if err != nil {
+		return err
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	if relPath == "." {
+		fmt.Println(".: the source root itself is always included")
+		return nil
+	}
+
+	info, statErr := os.Stat(absTarget)
+	isDir := statErr == nil && info.IsDir()
+
+	matcher := filter.New(absSource, includePaths, excludePaths)
+	if err := loadIgnoreFilesAbove(matcher, relPath); err != nil {
+		return err
+	}
+
+	included, rule := matcher.MatchRule(relPath, isDir)
+	verdict := "excluded"
+	if included {
+		verdict = "included"
+	}
+
+	if rule == nil {
+		fmt.Printf("%s: no rule matched, default is %s\n", relPath, verdict)
+		return nil
+	}
+	fmt.Printf("%s:%s\t%s\t(%s)\n", rule.Source, rule.Line, relPath, verdict)
+	return nil
+}
+
+// loadIgnoreFilesAbove loads every ".gorsyncignore" that a real walk would
+// have loaded by the time it reached relPath: one per ancestor directory,
+// root first, so a deeper file's rules can override a shallower one's.
+func loadIgnoreFilesAbove(m *filter.Matcher, relPath string) error {
+	segments := strings.Split(relPath, "/")
+	dir := ""
+	for _, seg := range segments[:len(segments)-1] {
+		if err := m.LoadIgnoreFile(dir); err != nil {
+			return err
+		}
+		if dir == "" {
+			dir = seg
+		} else {
+			dir = dir + "/" + seg
+		}
+	}
+	return m.LoadIgnoreFile(dir)
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// tryReflink attempts a copy-on-write clone of src onto dst via the FICLONE
+// ioctl. If the filesystem refuses it (EXDEV across filesystems, or
+// ENOTSUP/EOPNOTSUPP when the filesystem isn't CoW-capable), it falls back to
+// copy_file_range, which still lets the kernel do the copy without round
+// tripping the data through userspace. ok is false only when neither fast
+// path is usable, in which case the caller should fall back to io.Copy.
+func tryReflink(src, dst *os.File) (ok bool, err error) {
+	if ficloneErr := unix.IoctlSetInt(int(dst.Fd()), unix.FICLONE, int(src.Fd())); ficloneErr == nil {
+		return true, nil
+	} else if !isReflinkUnsupported(ficloneErr) {
+		return false, ficloneErr
+	}
+
+	info, err := src.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	var copied int64
+	remaining := info.Size()
+	for remaining > 0 {
+		n, err := unix.CopyFileRange(int(src.Fd()), nil, int(dst.Fd()), nil, int(remaining), 0)
+		if err != nil {
+			if isReflinkUnsupported(err) && copied == 0 {
+				return false, nil
+			}
+			return false, err
+		}
+		if n == 0 {
+			break
+		}
+		copied += int64(n)
+		remaining -= int64(n)
+	}
+	return true, nil
+}
+
+func isReflinkUnsupported(err error) bool {
+	return errors.Is(err, unix.EXDEV) || errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP)
+}
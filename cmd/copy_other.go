@@ -0,0 +1,11 @@
+//go:build !linux
+
+package cmd
+
+import "os"
+
+// tryReflink has no platform fast path outside Linux; the caller always
+// falls back to a plain io.Copy.
+func tryReflink(src, dst *os.File) (ok bool, err error) {
+	return false, nil
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+
+	"gorsyncv2/internal/archive"
+	"gorsyncv2/internal/config"
+	"gorsyncv2/internal/scheduler"
+)
+
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "Print the live status table for a running --config scheduler",
+	Long: `Print the live status table (name, next run, last run, last status) for the
+jobs declared in a --config file, as reported by the "gorsync backup --config"
+process currently running against it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := printJobsTable(configPath); err != nil {
+			fmt.Println("Error:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(jobsCmd)
+	jobsCmd.Flags().StringVar(&configPath, "config", "", "Path to the gorsync job config (required)")
+	jobsCmd.MarkFlagRequired("config")
+}
+
+func printJobsTable(path string) error {
+	rows, err := scheduler.ReadStatus(statusPath(path))
+	if err != nil {
+		return fmt.Errorf("no status found for %s (is \"gorsync backup --config=%s\" running?): %w", path, path, err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSCHEDULE\tNEXT RUN\tLAST RUN\tLAST STATUS")
+	for _, row := range rows {
+		lastRun, status := "-", "-"
+		if !row.LastRun.IsZero() {
+			lastRun = row.LastRun.Format(time.RFC3339)
+			status = "ok"
+			if row.LastErr != "" {
+				status = row.LastErr
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", row.Name, row.Schedule, row.NextRun.Format(time.RFC3339), lastRun, status)
+	}
+	return w.Flush()
+}
+
+// statusPath derives the scheduler's status file from the config path it was
+// started with, so "gorsync jobs" can read it without an RPC back into the
+// running process.
+func statusPath(configPath string) string {
+	return configPath + ".status.json"
+}
+
+// runConfigScheduler loads configPath, schedules every job it declares, and
+// blocks, reloading the job set whenever the process receives SIGHUP or
+// configPath's directory reports a write to it.
+func runConfigScheduler(configPath string) {
+	sched := scheduler.New(runConfiguredJob, statusPath(configPath))
+
+	reload := func() error {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		return sched.Reconcile(cfg.Jobs)
+	}
+
+	if err := reload(); err != nil {
+		fmt.Println("Error loading config:", err)
+		return
+	}
+	fmt.Printf("Loaded job config %s, starting scheduler\n", configPath)
+
+	sched.Start()
+	defer sched.Stop()
+
+	// Watch the config file's directory, since editors commonly replace the
+	// file (rename-over-write) rather than writing it in place, which would
+	// otherwise orphan a watch on the file itself.
+	var events chan fsnotify.Event
+	var watchErrs chan error
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Println("Warning: config file watching disabled:", err)
+	} else {
+		defer watcher.Close()
+		if err := watcher.Add(filepath.Dir(configPath)); err != nil {
+			fmt.Println("Warning: config file watching disabled:", err)
+		} else {
+			events = watcher.Events
+			watchErrs = watcher.Errors
+		}
+	}
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	for {
+		select {
+		case <-sighup:
+			if err := reload(); err != nil {
+				fmt.Println("Error reloading config:", err)
+			} else {
+				fmt.Println("Reloaded job config on SIGHUP")
+			}
+
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(configPath) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if err := reload(); err != nil {
+				fmt.Println("Error reloading config:", err)
+			} else {
+				fmt.Println("Reloaded job config after file change")
+			}
+
+		case err, ok := <-watchErrs:
+			if !ok {
+				watchErrs = nil
+				continue
+			}
+			fmt.Println("Config watcher error:", err)
+		}
+	}
+}
+
+// runConfiguredJob runs one job declared in a --config file, dispatching to
+// the mirror-copy or archive-destination path exactly like the single-job
+// --source/--destination flags do.
+func runConfiguredJob(job config.Job) error {
+	if archive.IsArchiveURI(job.Destination) {
+		return runArchiveBackupTo(job.Source, job.Destination, job.Include, job.Exclude, job.Compress, job.Encrypt, job.PassphraseFile, job.KeepDaily, job.KeepWeekly)
+	}
+	return syncDirectories(job.Source, job.Destination, job.Include, job.Exclude, job.Parallel, job.Reflink)
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"gorsyncv2/internal/filter"
+)
+
+// realtimeSync watches sourceDir for changes and mirrors them into backupDir
+// as they happen. Raw fsnotify events are coalesced per path behind a
+// debounce timer so editors that write-then-rename (vim, IDEs) produce one
+// copy instead of several, and a directory created after startup is walked
+// and watched recursively since fsnotify itself is not recursive.
+type realtimeSync struct {
+	watcher       *fsnotify.Watcher
+	matcher       *filter.Matcher
+	sourceDir     string
+	backupDir     string
+	debounce      time.Duration
+	mirrorDeletes bool
+
+	mu      sync.Mutex
+	pending map[string]*pendingEvent
+}
+
+// pendingEvent is the coalesced state for one path: every fsnotify event
+// that arrives before the debounce timer fires ORs its Op in and resets the
+// timer, so only the final state is acted on.
+type pendingEvent struct {
+	op    fsnotify.Op
+	timer *time.Timer
+}
+
+func runRealTimeSync() {
+	fmt.Printf("Starting real-time sync at %s\n", time.Now().Format("2006-01-02 15:04:05"))
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Println("Error creating watcher:", err)
+		return
+	}
+	defer watcher.Close()
+
+	rts := &realtimeSync{
+		watcher:       watcher,
+		matcher:       filter.New(sourceDir, includePaths, excludePaths),
+		sourceDir:     sourceDir,
+		backupDir:     backupDir,
+		debounce:      debouncePeriod,
+		mirrorDeletes: mirrorDeletes,
+		pending:       make(map[string]*pendingEvent),
+	}
+
+	// --include and --exclude are patterns, not paths, so walk all of
+	// sourceDir and let watchTree's matcher decide what to watch.
+	if err := rts.watchTree(sourceDir); err != nil {
+		fmt.Println("Error walking source directory:", err)
+		return
+	}
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			rts.schedule(event)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Println("Error:", err)
+		}
+	}
+}
+
+// watchTree adds root and every included subdirectory beneath it to the
+// watcher, loading ".gorsyncignore" files as it goes. It is used for the
+// initial walk of sourceDir; watchNewSubtree is the analogous walk for a
+// subtree created after startup, which additionally has to materialize the
+// directory under backupDir and copy whatever files already exist inside it.
+func (rts *realtimeSync) watchTree(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(rts.sourceDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if relPath != "." && !rts.matcher.Match(relPath, true) && !rts.matcher.CouldContainMatch(relPath) {
+			return filepath.SkipDir
+		}
+
+		if err := rts.matcher.LoadIgnoreFile(relSlashOrRoot(relPath)); err != nil {
+			return err
+		}
+
+		return rts.watcher.Add(path)
+	})
+}
+
+// schedule resets the debounce timer for event.Name, folding its Op into
+// whatever is already pending for that path so a burst of events collapses
+// into a single, final action.
+func (rts *realtimeSync) schedule(event fsnotify.Event) {
+	rts.mu.Lock()
+	defer rts.mu.Unlock()
+
+	if pe, ok := rts.pending[event.Name]; ok {
+		pe.op |= event.Op
+		pe.timer.Reset(rts.debounce)
+		return
+	}
+
+	name := event.Name
+	pe := &pendingEvent{op: event.Op}
+	pe.timer = time.AfterFunc(rts.debounce, func() { rts.fire(name) })
+	rts.pending[name] = pe
+}
+
+// fire acts on the coalesced state accumulated for name since it was last
+// scheduled. It runs on the timer's own goroutine.
+func (rts *realtimeSync) fire(name string) {
+	rts.mu.Lock()
+	pe, ok := rts.pending[name]
+	if ok {
+		delete(rts.pending, name)
+	}
+	rts.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	relPath, err := filepath.Rel(rts.sourceDir, name)
+	if err != nil {
+		fmt.Println("Error getting relative path:", err)
+		return
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	info, statErr := os.Stat(name)
+	if statErr != nil {
+		if pe.op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+			rts.mirrorDelete(relPath)
+		}
+		return
+	}
+
+	if !rts.matcher.Match(relPath, info.IsDir()) {
+		return
+	}
+
+	if info.IsDir() {
+		if pe.op&fsnotify.Create != 0 {
+			rts.watchNewSubtree(name)
+		}
+		return
+	}
+
+	fmt.Println("Detected modification:", name)
+	if err := copyFileWithProgress(name, filepath.Join(rts.backupDir, relPath)); err != nil {
+		fmt.Println("Error copying", name, ":", err)
+	}
+}
+
+// watchNewSubtree handles a directory created after startup: fsnotify is not
+// recursive, so a plain watcher.Add on it would miss both the subtree's own
+// descendants and any files already written into it before the watch was
+// added (e.g. "mkdir -p a/b/c && cp file a/b/c/"), so it is walked and each
+// file copied exactly like the initial scan.
+func (rts *realtimeSync) watchNewSubtree(root string) {
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(rts.sourceDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		matched := rts.matcher.Match(relPath, info.IsDir())
+		if !matched {
+			if !info.IsDir() {
+				return nil
+			}
+			if !rts.matcher.CouldContainMatch(relPath) {
+				return filepath.SkipDir
+			}
+			// relPath itself doesn't match, but a descendant might, so
+			// keep walking and still watch the directory below.
+		}
+
+		if info.IsDir() {
+			if err := rts.matcher.LoadIgnoreFile(relSlashOrRoot(relPath)); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Join(rts.backupDir, relPath), info.Mode()); err != nil {
+				return err
+			}
+			return rts.watcher.Add(path)
+		}
+
+		fmt.Println("Detected modification:", path)
+		if err := copyFileWithProgress(path, filepath.Join(rts.backupDir, relPath)); err != nil {
+			fmt.Println("Error copying", path, ":", err)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Println("Error watching new subtree", root, ":", err)
+	}
+}
+
+// mirrorDelete removes backupDir's copy of relPath. It is a no-op unless
+// --mirror-deletes was passed, since leaving a deleted source file in place
+// at the destination is still the default.
+func (rts *realtimeSync) mirrorDelete(relPath string) {
+	if !rts.mirrorDeletes {
+		return
+	}
+	destPath := filepath.Join(rts.backupDir, relPath)
+	if err := os.RemoveAll(destPath); err != nil {
+		fmt.Println("Error removing", destPath, ":", err)
+		return
+	}
+	fmt.Println("Removed", destPath)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"gorsyncv2/internal/snapshot"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Create a deduplicated, content-addressed snapshot of a directory",
+	Long: `Create a deduplicated, content-addressed snapshot of a directory.
+Files are split into content-defined chunks and stored once regardless of how
+many snapshots reference them, so repeated backups of slowly-changing data
+cost roughly the size of what changed rather than the size of the whole
+tree.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := snapshot.Create(sourceDir, backupDir)
+		if err != nil {
+			fmt.Println("Error creating snapshot:", err)
+			return
+		}
+		fmt.Printf("Created snapshot %s\n", id)
+	},
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the snapshots stored in the destination repository",
+	Run: func(cmd *cobra.Command, args []string) {
+		ids, err := snapshot.List(backupDir)
+		if err != nil {
+			fmt.Println("Error listing snapshots:", err)
+			return
+		}
+		for _, id := range ids {
+			fmt.Println(id)
+		}
+	},
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <id> <dst>",
+	Short: "Restore a snapshot to a destination directory",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := snapshot.Restore(backupDir, args[0], args[1]); err != nil {
+			fmt.Println("Error restoring snapshot:", err)
+			return
+		}
+		fmt.Printf("Restored snapshot %s to %s\n", args[0], args[1])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+
+	snapshotCmd.PersistentFlags().StringVarP(&backupDir, "destination", "d", "", "Snapshot repository directory (required)")
+	snapshotCmd.Flags().StringVarP(&sourceDir, "source", "s", "", "Source directory to snapshot (required)")
+
+	snapshotCmd.MarkFlagRequired("source")
+	snapshotCmd.MarkPersistentFlagRequired("destination")
+}
@@ -0,0 +1,179 @@
+package archive
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Archive encryption is a simple streaming AEAD format: a random salt
+// header, followed by a sequence of length-prefixed AES-256-GCM sealed
+// chunks (each up to plainChunkSize of plaintext), with each chunk's nonce
+// derived from a monotonically increasing counter so no nonce is ever
+// reused under the same key.
+const (
+	scryptN        = 1 << 15
+	scryptR        = 8
+	scryptP        = 1
+	saltSize       = 16
+	nonceSize      = 12
+	plainChunkSize = 64 * 1024
+)
+
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newEncryptWriter wraps w so that everything written to the returned
+// WriteCloser is sealed with AES-256-GCM under a key derived from
+// passphrase. Close must be called to flush the final chunk.
+func newEncryptWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(salt); err != nil {
+		return nil, err
+	}
+
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{w: w, gcm: gcm, buf: make([]byte, 0, plainChunkSize)}, nil
+}
+
+type encryptWriter struct {
+	w   io.Writer
+	gcm cipher.AEAD
+	buf []byte
+	seq uint64
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	total := len(p)
+	for len(p) > 0 {
+		n := copy(e.buf[len(e.buf):cap(e.buf)], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		if len(e.buf) == plainChunkSize {
+			if err := e.flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return total, nil
+}
+
+func (e *encryptWriter) flush() error {
+	nonce := make([]byte, nonceSize)
+	binary.BigEndian.PutUint64(nonce[4:], e.seq)
+	e.seq++
+
+	sealed := e.gcm.Seal(nil, nonce, e.buf, nil)
+	var lenPrefix [4]byte
+	binary.BigEndian.PutUint32(lenPrefix[:], uint32(len(sealed)))
+	if _, err := e.w.Write(lenPrefix[:]); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// Close flushes the final (possibly partial, possibly empty) chunk, which
+// doubles as an explicit end-of-stream marker for newDecryptReader: Write
+// flushes every full chunk itself, so only the final one is ever shorter
+// than plainChunkSize.
+func (e *encryptWriter) Close() error {
+	return e.flush()
+}
+
+// newDecryptReader reverses newEncryptWriter.
+func newDecryptReader(r io.Reader, passphrase string) (io.Reader, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(r, salt); err != nil {
+		return nil, err
+	}
+
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptReader{r: r, gcm: gcm}, nil
+}
+
+type decryptReader struct {
+	r   io.Reader
+	gcm cipher.AEAD
+	seq uint64
+	buf []byte
+	// done is set once the final, shorter-than-plainChunkSize chunk has
+	// been read.
+	done bool
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		var lenPrefix [4]byte
+		if _, err := io.ReadFull(d.r, lenPrefix[:]); err != nil {
+			if err == io.EOF {
+				return 0, fmt.Errorf("decrypting archive: stream ends before its final chunk")
+			}
+			if err == io.ErrUnexpectedEOF {
+				return 0, fmt.Errorf("decrypting archive: truncated chunk header")
+			}
+			return 0, err
+		}
+
+		sealed := make([]byte, binary.BigEndian.Uint32(lenPrefix[:]))
+		if _, err := io.ReadFull(d.r, sealed); err != nil {
+			return 0, fmt.Errorf("decrypting archive: truncated chunk")
+		}
+
+		nonce := make([]byte, nonceSize)
+		binary.BigEndian.PutUint64(nonce[4:], d.seq)
+		d.seq++
+
+		plain, err := d.gcm.Open(nil, nonce, sealed, nil)
+		if err != nil {
+			return 0, fmt.Errorf("decrypting archive: wrong passphrase or corrupt data: %w", err)
+		}
+		d.buf = plain
+		if len(plain) < plainChunkSize {
+			d.done = true
+		}
+	}
+
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,61 @@
+package archive
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+)
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	plain := make([]byte, 3*plainChunkSize+123)
+	if _, err := rand.Read(plain); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+
+	var sealed bytes.Buffer
+	w, err := newEncryptWriter(&sealed, "correct horse battery staple")
+	if err != nil {
+		t.Fatalf("newEncryptWriter: %v", err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r, err := newDecryptReader(&sealed, "correct horse battery staple")
+	if err != nil {
+		t.Fatalf("newDecryptReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, plain) {
+		t.Fatalf("round-tripped data does not match: got %d bytes, want %d", len(got), len(plain))
+	}
+}
+
+func TestDecryptWrongPassphraseFails(t *testing.T) {
+	var sealed bytes.Buffer
+	w, err := newEncryptWriter(&sealed, "the right passphrase")
+	if err != nil {
+		t.Fatalf("newEncryptWriter: %v", err)
+	}
+	if _, err := w.Write([]byte("some archive contents")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r, err := newDecryptReader(&sealed, "the wrong passphrase")
+	if err != nil {
+		t.Fatalf("newDecryptReader: %v", err)
+	}
+	if _, err := io.ReadAll(r); err == nil {
+		t.Fatal("expected an error decrypting with the wrong passphrase, got nil")
+	}
+}
@@ -0,0 +1,188 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Reader streams files back out of an archive created by a Writer.
+type Reader interface {
+	// Next advances to the next entry, returning its path (relative to the
+	// archive root), file info, and a reader positioned at its contents
+	// (non-nil for directories too, with no data to read). It returns
+	// io.EOF once the archive is exhausted.
+	Next() (string, os.FileInfo, io.ReadCloser, error)
+	Close() error
+}
+
+// NewReader opens spec.Path and returns a Reader that reverses whatever
+// encryption and compression the archive was written with.
+func NewReader(spec Spec, passphrase string) (Reader, error) {
+	if spec.Format == "zip" {
+		return newZipReader(spec, passphrase)
+	}
+	return newTarReader(spec, passphrase)
+}
+
+func newTarReader(spec Spec, passphrase string) (Reader, error) {
+	f, err := os.Open(spec.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = f
+	closers := []io.Closer{f}
+
+	if spec.Encrypt {
+		dr, err := newDecryptReader(r, passphrase)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		r = dr
+	}
+
+	switch spec.Compress {
+	case "gzip":
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		r = gr
+		closers = append(closers, gr)
+	case "zstd":
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		r = zr
+		closers = append(closers, zstdDecoderCloser{zr})
+	case "none":
+	default:
+		f.Close()
+		return nil, fmt.Errorf("unsupported compression %q", spec.Compress)
+	}
+
+	return &tarReader{tr: tar.NewReader(r), closers: closers}, nil
+}
+
+type tarReader struct {
+	tr      *tar.Reader
+	closers []io.Closer
+}
+
+func (t *tarReader) Next() (string, os.FileInfo, io.ReadCloser, error) {
+	hdr, err := t.tr.Next()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return hdr.Name, hdr.FileInfo(), io.NopCloser(t.tr), nil
+}
+
+func (t *tarReader) Close() error {
+	var firstErr error
+	for i := len(t.closers) - 1; i >= 0; i-- {
+		if err := t.closers[i].Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// zstdDecoderCloser adapts *zstd.Decoder's no-return-value Close to io.Closer.
+type zstdDecoderCloser struct{ *zstd.Decoder }
+
+func (z zstdDecoderCloser) Close() error {
+	z.Decoder.Close()
+	return nil
+}
+
+// newZipReader opens a zip archive. Because archive/zip needs random access
+// to read the central directory at the end of the file, an encrypted zip is
+// first decrypted into a temporary file (zip never carries the extra
+// +gzip/+zstd compression layer, since its entries already compress
+// themselves).
+func newZipReader(spec Spec, passphrase string) (Reader, error) {
+	path := spec.Path
+	var tmp string
+
+	if spec.Encrypt {
+		t, err := decryptToTempFile(spec.Path, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		path = t
+		tmp = t
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		if tmp != "" {
+			os.Remove(tmp)
+		}
+		return nil, err
+	}
+
+	return &zipReader{zr: zr, tmp: tmp}, nil
+}
+
+func decryptToTempFile(path, passphrase string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	dr, err := newDecryptReader(f, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp("", "gorsync-archive-*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, dr); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+type zipReader struct {
+	zr  *zip.ReadCloser
+	idx int
+	tmp string
+}
+
+func (z *zipReader) Next() (string, os.FileInfo, io.ReadCloser, error) {
+	if z.idx >= len(z.zr.File) {
+		return "", nil, nil, io.EOF
+	}
+	f := z.zr.File[z.idx]
+	z.idx++
+
+	rc, err := f.Open()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return f.Name, f.FileInfo(), rc, nil
+}
+
+func (z *zipReader) Close() error {
+	err := z.zr.Close()
+	if z.tmp != "" {
+		os.Remove(z.tmp)
+	}
+	return err
+}
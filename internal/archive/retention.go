@@ -0,0 +1,72 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Retention describes how many recent archives to keep at each granularity.
+// Zero keeps no archives at that granularity; pruning is disabled only when
+// both are zero.
+type Retention struct {
+	KeepDaily  int
+	KeepWeekly int
+}
+
+// Prune removes archives matching pattern (a glob, typically spec.Pattern)
+// beyond what the retention policy says to keep, based on file mtime.
+func Prune(pattern string, r Retention) error {
+	if r.KeepDaily == 0 && r.KeepWeekly == 0 {
+		return nil
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+
+	type archiveFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []archiveFile
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil {
+			continue
+		}
+		files = append(files, archiveFile{path: m, modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+
+	keep := make(map[string]bool, len(files))
+	dailySeen := make(map[string]bool)
+	weeklySeen := make(map[string]bool)
+	for _, f := range files {
+		day := f.modTime.Format("2006-01-02")
+		year, week := f.modTime.ISOWeek()
+		weekKey := fmt.Sprintf("%d-W%02d", year, week)
+
+		if r.KeepDaily > 0 && !dailySeen[day] && len(dailySeen) < r.KeepDaily {
+			dailySeen[day] = true
+			keep[f.path] = true
+		}
+		if r.KeepWeekly > 0 && !weeklySeen[weekKey] && len(weeklySeen) < r.KeepWeekly {
+			weeklySeen[weekKey] = true
+			keep[f.path] = true
+		}
+	}
+
+	for _, f := range files {
+		if keep[f.path] {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
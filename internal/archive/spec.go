@@ -0,0 +1,63 @@
+// Package archive streams a walked directory tree into a single compressed,
+// optionally encrypted archive file (tar or zip), and reverses that pipeline
+// to restore one. Archive destinations are addressed by URI, e.g.
+// "tar+gzip:///mnt/backup/backup-{date}.tar.gz" or "zip:///mnt/backup/backup.zip".
+package archive
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Spec describes a parsed archive destination URI.
+type Spec struct {
+	Format   string // "tar" or "zip"
+	Compress string // "none", "gzip", or "zstd"
+	Encrypt  bool   // true if the "age" scheme component is present
+
+	// Path is the destination file path with "{date}" expanded to the
+	// current time. Pattern keeps "{date}" as a "*" glob instead, for
+	// matching sibling archives when applying a retention policy.
+	Path    string
+	Pattern string
+}
+
+// IsArchiveURI reports whether dest looks like an archive destination
+// (scheme://path) rather than a plain mirrored directory.
+func IsArchiveURI(dest string) bool {
+	return strings.Contains(dest, "://")
+}
+
+// ParseSpec parses an archive destination URI into its component parts.
+// defaultCompress and defaultEncrypt seed Compress/Encrypt for schemes that
+// don't name a compressor or "age" explicitly, so the --compress/--encrypt
+// flags still apply to a bare "tar://" or "zip://" destination.
+func ParseSpec(dest, defaultCompress string, defaultEncrypt bool) (Spec, error) {
+	parts := strings.SplitN(dest, "://", 2)
+	if len(parts) != 2 {
+		return Spec{}, fmt.Errorf("invalid archive destination %q: missing scheme", dest)
+	}
+
+	spec := Spec{Format: "tar", Compress: defaultCompress, Encrypt: defaultEncrypt}
+	for _, component := range strings.Split(parts[0], "+") {
+		switch component {
+		case "tar", "zip":
+			spec.Format = component
+		case "gzip", "zstd":
+			spec.Compress = component
+		case "age":
+			spec.Encrypt = true
+		default:
+			return Spec{}, fmt.Errorf("invalid archive destination %q: unknown scheme component %q", dest, component)
+		}
+	}
+
+	if spec.Format == "zip" && spec.Compress != "none" {
+		return Spec{}, fmt.Errorf("invalid archive destination %q: zip already compresses its members, --compress/+gzip/+zstd is not supported", dest)
+	}
+
+	spec.Pattern = strings.ReplaceAll(parts[1], "{date}", "*")
+	spec.Path = strings.ReplaceAll(parts[1], "{date}", time.Now().Format("20060102-150405"))
+	return spec, nil
+}
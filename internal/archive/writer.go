@@ -0,0 +1,158 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Writer streams files into a single archive destination.
+type Writer interface {
+	// WriteFile adds one entry. r is nil for directories.
+	WriteFile(relPath string, info os.FileInfo, r io.Reader) error
+	Close() error
+}
+
+// NewWriter opens spec.Path and returns a Writer that streams spec.Format
+// archive entries through spec.Compress compression and, if spec.Encrypt,
+// through AES-256-GCM encryption keyed from passphrase.
+func NewWriter(spec Spec, passphrase string) (Writer, error) {
+	f, err := os.Create(spec.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := wrapCompressAndEncrypt(f, spec, passphrase)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	switch spec.Format {
+	case "zip":
+		return &zipWriter{zw: zip.NewWriter(w), closer: w}, nil
+	case "tar":
+		return &tarWriter{tw: tar.NewWriter(w), closer: w}, nil
+	default:
+		w.Close()
+		return nil, fmt.Errorf("unsupported archive format %q", spec.Format)
+	}
+}
+
+// wrapCompressAndEncrypt layers encryption outside compression (so the
+// ciphertext, not the plaintext, hits disk) around the raw destination file.
+func wrapCompressAndEncrypt(f *os.File, spec Spec, passphrase string) (io.WriteCloser, error) {
+	var w io.WriteCloser = f
+
+	if spec.Encrypt {
+		ew, err := newEncryptWriter(w, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		w = &chainWriteCloser{WriteCloser: ew, inner: w}
+	}
+
+	switch spec.Compress {
+	case "gzip":
+		w = &chainWriteCloser{WriteCloser: gzip.NewWriter(w), inner: w}
+	case "zstd":
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		w = &chainWriteCloser{WriteCloser: zw, inner: w}
+	case "none":
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", spec.Compress)
+	}
+
+	return w, nil
+}
+
+// chainWriteCloser closes an outer stream (e.g. a compressor) and then the
+// inner one it wraps (e.g. an encryptor, or the destination file), since
+// closing the outer layer alone only flushes its own footer.
+type chainWriteCloser struct {
+	io.WriteCloser
+	inner io.Closer
+}
+
+func (c *chainWriteCloser) Close() error {
+	if err := c.WriteCloser.Close(); err != nil {
+		c.inner.Close()
+		return err
+	}
+	return c.inner.Close()
+}
+
+type tarWriter struct {
+	tw     *tar.Writer
+	closer io.Closer
+}
+
+func (a *tarWriter) WriteFile(relPath string, info os.FileInfo, r io.Reader) error {
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = relPath
+	if info.IsDir() {
+		hdr.Name += "/"
+	}
+	if err := a.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+	_, err = io.Copy(a.tw, r)
+	return err
+}
+
+func (a *tarWriter) Close() error {
+	if err := a.tw.Close(); err != nil {
+		a.closer.Close()
+		return err
+	}
+	return a.closer.Close()
+}
+
+type zipWriter struct {
+	zw     *zip.Writer
+	closer io.Closer
+}
+
+func (a *zipWriter) WriteFile(relPath string, info os.FileInfo, r io.Reader) error {
+	hdr, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	hdr.Name = relPath
+
+	if info.IsDir() {
+		hdr.Name += "/"
+		_, err := a.zw.CreateHeader(hdr)
+		return err
+	}
+
+	hdr.Method = zip.Deflate
+	w, err := a.zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+func (a *zipWriter) Close() error {
+	if err := a.zw.Close(); err != nil {
+		a.closer.Close()
+		return err
+	}
+	return a.closer.Close()
+}
@@ -0,0 +1,85 @@
+// Package config loads the YAML job file consumed by "gorsync backup
+// --config", which declares one or more independently scheduled backup jobs
+// in place of the single --source/--destination/--interval flag set.
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Job is one named backup job: a source/destination pair plus its own
+// schedule, filters, and retention, mirroring the flags accepted by
+// "gorsync backup" itself.
+type Job struct {
+	Name        string   `yaml:"name"`
+	Source      string   `yaml:"source"`
+	Destination string   `yaml:"destination"`
+	Include     []string `yaml:"include"`
+	Exclude     []string `yaml:"exclude"`
+
+	// Schedule is a standard five-field cron expression (e.g. "0 */2 * * *"),
+	// not a time.Duration interval.
+	Schedule string `yaml:"schedule"`
+	Parallel int    `yaml:"parallel"`
+	Reflink  string `yaml:"reflink"`
+
+	// Compress, Encrypt, and PassphraseFile only apply when Destination is an
+	// archive URI (see internal/archive).
+	Compress       string `yaml:"compress"`
+	Encrypt        bool   `yaml:"encrypt"`
+	PassphraseFile string `yaml:"passphrase_file"`
+	KeepDaily      int    `yaml:"keep_daily"`
+	KeepWeekly     int    `yaml:"keep_weekly"`
+}
+
+// Config is the top-level shape of a --config YAML file.
+type Config struct {
+	Jobs []Job `yaml:"jobs"`
+}
+
+// Load reads and validates the job config at path, filling in the same
+// defaults the "gorsync backup" flags use for an unconfigured job.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool, len(cfg.Jobs))
+	for i, job := range cfg.Jobs {
+		if job.Name == "" {
+			return nil, fmt.Errorf("job %d: name is required", i)
+		}
+		if seen[job.Name] {
+			return nil, fmt.Errorf("job %q: duplicate name", job.Name)
+		}
+		seen[job.Name] = true
+
+		if job.Source == "" || job.Destination == "" {
+			return nil, fmt.Errorf("job %q: source and destination are required", job.Name)
+		}
+		if job.Schedule == "" {
+			return nil, fmt.Errorf("job %q: schedule is required", job.Name)
+		}
+
+		if job.Parallel == 0 {
+			cfg.Jobs[i].Parallel = 4
+		}
+		if job.Reflink == "" {
+			cfg.Jobs[i].Reflink = "auto"
+		}
+		if job.Compress == "" {
+			cfg.Jobs[i].Compress = "none"
+		}
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,275 @@
+// Package filter implements gitignore-style include/exclude matching: "**"
+// for arbitrary depth, "*" for a single path segment, a leading "/" anchors
+// a pattern to its base directory, a trailing "/" restricts it to
+// directories, and a leading "!" negates it. A Matcher is built once from
+// the --include/--exclude flags and then grows as a walk discovers
+// per-directory ".gorsyncignore" files, so it can be shared by both
+// syncDirectories and the fsnotify event handler.
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// IgnoreFileName is the gitignore-style file a Matcher loads from each
+// directory a walk descends into.
+const IgnoreFileName = ".gorsyncignore"
+
+// Rule is one compiled include/exclude/ignore pattern, kept around (rather
+// than discarded after compiling) so "gorsync check-ignore" can report which
+// rule, and from which source, decided a path's outcome.
+type Rule struct {
+	// Source names where the rule came from: "--include", "--exclude", or
+	// the path of the .gorsyncignore file it was read from.
+	Source string
+	// Line is the raw, unparsed pattern text.
+	Line string
+
+	Negate   bool
+	DirOnly  bool
+	Anchored bool
+	// Base is the directory (slash-separated, relative to the Matcher's
+	// root, "" for the root itself) this rule is scoped under.
+	Base    string
+	Pattern string
+}
+
+func (r Rule) matches(relPath string, isDir bool) bool {
+	if r.DirOnly && !isDir {
+		return false
+	}
+
+	rel := relPath
+	if r.Base != "" {
+		switch {
+		case rel == r.Base:
+			rel = ""
+		case strings.HasPrefix(rel, r.Base+"/"):
+			rel = strings.TrimPrefix(rel, r.Base+"/")
+		default:
+			return false
+		}
+	}
+
+	pattern := r.Pattern
+	if !r.Anchored {
+		pattern = "**/" + pattern
+	}
+
+	ok, _ := doublestar.Match(pattern, rel)
+	return ok
+}
+
+// mayContainMatch reports whether relPath, a directory that doesn't itself
+// match, could still have a descendant that does — e.g. "a" and "a/b" for
+// the pattern "a/b/c/**", which only matches at or below "a/b/c" and so
+// would otherwise look unreachable to a walker that prunes on the first
+// non-match. A non-anchored rule (a bare pattern with no "/", matched as
+// "**/pattern") can match at any depth, so any directory might still
+// contain a hit.
+func (r Rule) mayContainMatch(relPath string) bool {
+	if !r.Anchored {
+		return true
+	}
+
+	rel := relPath
+	if r.Base != "" {
+		switch {
+		case rel == r.Base:
+			rel = ""
+		case strings.HasPrefix(rel, r.Base+"/"):
+			rel = strings.TrimPrefix(rel, r.Base+"/")
+		default:
+			// relPath might itself be an ancestor of r.Base.
+			return r.Base == rel || strings.HasPrefix(r.Base, rel+"/")
+		}
+	}
+
+	relSegs := strings.Split(rel, "/")
+	patSegs := strings.Split(r.Pattern, "/")
+	for i, seg := range patSegs {
+		if i >= len(relSegs) {
+			return true
+		}
+		if strings.ContainsAny(seg, "*?[") {
+			return true
+		}
+		if seg != relSegs[i] {
+			return false
+		}
+	}
+	return false
+}
+
+// parsePattern compiles one gitignore-style pattern line, scoped to base
+// (the directory it was declared in, relative to the sync root), recording
+// source for debugging output.
+func parsePattern(line, base, source string) Rule {
+	raw := line
+
+	negate := false
+	if strings.HasPrefix(raw, "!") {
+		negate = true
+		raw = raw[1:]
+	}
+
+	dirOnly := false
+	if strings.HasSuffix(raw, "/") {
+		dirOnly = true
+		raw = strings.TrimSuffix(raw, "/")
+	}
+
+	anchored := strings.HasPrefix(raw, "/")
+	raw = strings.TrimPrefix(raw, "/")
+	if !anchored && strings.Contains(raw, "/") {
+		// A pattern with a slash anywhere but the end is anchored to its
+		// base directory, exactly like a gitignore pattern.
+		anchored = true
+	}
+
+	return Rule{
+		Source:   source,
+		Line:     line,
+		Negate:   negate,
+		DirOnly:  dirOnly,
+		Anchored: anchored,
+		Base:     base,
+		Pattern:  raw,
+	}
+}
+
+// Matcher decides whether a path under root should be included, combining
+// --include/--exclude patterns with any ".gorsyncignore" files discovered
+// while walking root. A Matcher is shared across the backup worker pool and,
+// for real-time sync, across per-path debounce timers that fire on their
+// own goroutines, so every method is safe to call concurrently: mu guards
+// ignores (the only field mutated after construction).
+type Matcher struct {
+	root     string
+	includes []Rule
+
+	mu      sync.RWMutex
+	ignores []Rule
+}
+
+// New builds a Matcher scoped to root from the given --include and
+// --exclude patterns. Call LoadIgnoreFile as a walk descends into root to
+// pick up ".gorsyncignore" files along the way.
+func New(root string, includePatterns, excludePatterns []string) *Matcher {
+	m := &Matcher{root: root}
+	for _, p := range includePatterns {
+		m.includes = append(m.includes, parsePattern(p, "", "--include"))
+	}
+	for _, p := range excludePatterns {
+		m.ignores = append(m.ignores, parsePattern(p, "", "--exclude"))
+	}
+	return m
+}
+
+// snapshotIgnores returns the ignore rules in effect right now, for a
+// reader to consult without holding m.mu for the whole comparison loop.
+func (m *Matcher) snapshotIgnores() []Rule {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.ignores
+}
+
+// LoadIgnoreFile reads root/relDir/.gorsyncignore, if present, and appends
+// its rules scoped to relDir ("" for the root itself). Call it once per
+// directory, root-first, as a walk descends, so a subdirectory's rules are
+// applied after (and can override) its ancestors', the same as git.
+func (m *Matcher) LoadIgnoreFile(relDir string) error {
+	path := filepath.Join(m.root, relDir, IgnoreFileName)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var rules []Rule
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		rules = append(rules, parsePattern(trimmed, relDir, path))
+	}
+
+	m.mu.Lock()
+	m.ignores = append(m.ignores, rules...)
+	m.mu.Unlock()
+	return nil
+}
+
+// Match reports whether relPath (slash-separated, relative to root) should
+// be included.
+func (m *Matcher) Match(relPath string, isDir bool) bool {
+	included, _ := m.MatchRule(relPath, isDir)
+	return included
+}
+
+// CouldContainMatch reports whether relPath, a directory that Match just
+// rejected, might still contain a file an --include pattern matches
+// further down (see Rule.mayContainMatch). Walkers should use this, not a
+// plain !Match, to decide whether filepath.SkipDir is safe: a multi-segment
+// pattern like "a/b/c/**" never matches its own ancestors "a" or "a/b", so
+// skipping on !Match alone would prune the tree before ever reaching it.
+// A directory an --exclude or ".gorsyncignore" rule rejects never can, as
+// in git. Otherwise, with no --include patterns at all, everything is
+// included by default and there is nothing to prune around.
+func (m *Matcher) CouldContainMatch(relPath string) bool {
+	if included, rule := m.MatchRule(relPath, true); !included && rule != nil {
+		return false
+	}
+	if len(m.includes) == 0 {
+		return true
+	}
+	for i := range m.includes {
+		if m.includes[i].mayContainMatch(relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchRule is Match plus the Rule that decided the outcome, or a nil Rule
+// if nothing matched and the default applied. Rules are evaluated in
+// declaration order, includes before ignores, ancestor ".gorsyncignore"
+// files before descendants', so the last match wins exactly as in git.
+func (m *Matcher) MatchRule(relPath string, isDir bool) (bool, *Rule) {
+	// As in git, a path beneath a directory an ignore rule excludes is
+	// excluded by that same rule, whatever later rules say about the path.
+	if i := strings.LastIndex(relPath, "/"); i >= 0 {
+		if parentIncluded, rule := m.MatchRule(relPath[:i], true); !parentIncluded && rule != nil {
+			return false, rule
+		}
+	}
+
+	included := len(m.includes) == 0
+	var decided *Rule
+
+	for i := range m.includes {
+		if m.includes[i].matches(relPath, isDir) {
+			included = true
+			decided = &m.includes[i]
+		}
+	}
+	ignores := m.snapshotIgnores()
+	for i := range ignores {
+		if ignores[i].matches(relPath, isDir) {
+			included = ignores[i].Negate
+			decided = &ignores[i]
+		}
+	}
+
+	return included, decided
+}
@@ -0,0 +1,127 @@
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestMatchDefaultIncludesEverything(t *testing.T) {
+	m := New("/root", nil, nil)
+	if !m.Match("anything/at/all.txt", false) {
+		t.Fatal("with no --include/--exclude, every path should be included")
+	}
+}
+
+func TestMatchIncludeExcludeNegatePrecedence(t *testing.T) {
+	m := New("/root", []string{"*.go"}, []string{"*_test.go", "!keep_test.go"})
+
+	cases := []struct {
+		path string
+		want bool
+	}{
+		{"main.go", true},       // matches --include, no --exclude hits
+		{"main_test.go", false}, // --include, then --exclude overrides
+		{"keep_test.go", true},  // --include, --exclude, then negated back in
+		{"README.md", false},    // doesn't match the only --include pattern
+	}
+	for _, c := range cases {
+		if got := m.Match(c.path, false); got != c.want {
+			t.Errorf("Match(%q) = %v, want %v", c.path, got, c.want)
+		}
+	}
+}
+
+func TestMatchIgnoreFileOverridesAncestor(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gorsyncignore"), []byte("*.log\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "sub", ".gorsyncignore"), []byte("!keep.log\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(root, nil, nil)
+	if err := m.LoadIgnoreFile(""); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.LoadIgnoreFile("sub"); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Match("sub/other.log", false) {
+		t.Error("sub/other.log should still be excluded by the root .gorsyncignore")
+	}
+	if !m.Match("sub/keep.log", false) {
+		t.Error("sub/keep.log should be re-included by sub's .gorsyncignore negation")
+	}
+	if !m.Match("top.log", false) == false {
+		// top.log isn't under sub, so only the root rule applies: excluded.
+		t.Error("top.log should be excluded by the root .gorsyncignore")
+	}
+}
+
+func TestCouldContainMatchForMultiSegmentInclude(t *testing.T) {
+	m := New("/root", []string{"a/b/c/**"}, nil)
+
+	for _, ancestor := range []string{"a", "a/b"} {
+		if m.Match(ancestor, true) {
+			t.Errorf("Match(%q) = true, want false (pattern only matches at/below a/b/c)", ancestor)
+		}
+		if !m.CouldContainMatch(ancestor) {
+			t.Errorf("CouldContainMatch(%q) = false, want true so the walker doesn't prune it", ancestor)
+		}
+	}
+
+	if !m.Match("a/b/c", true) {
+		t.Error("a/b/c itself should match a/b/c/**")
+	}
+	if m.CouldContainMatch("x") {
+		t.Error("an unrelated top-level directory should not be flagged as possibly containing a match")
+	}
+}
+
+func TestCouldContainMatchWithNoIncludes(t *testing.T) {
+	m := New("/root", nil, []string{"*.tmp"})
+	if !m.CouldContainMatch("anything") {
+		t.Error("with no --include patterns, every directory could still contain a match")
+	}
+}
+
+// TestConcurrentLoadIgnoreFileAndMatch exercises the same pattern
+// realtimeSync does in cmd/realtime.go: a shared Matcher consulted and
+// extended from multiple goroutines at once (one per debounced path). It is
+// meant to be run with -race.
+func TestConcurrentLoadIgnoreFileAndMatch(t *testing.T) {
+	root := t.TempDir()
+	const n = 20
+	for i := 0; i < n; i++ {
+		dir := filepath.Join(root, "d", string(rune('a'+i)))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, IgnoreFileName), []byte("*.tmp\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := New(root, nil, nil)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rel := filepath.ToSlash(filepath.Join("d", string(rune('a'+i))))
+			if err := m.LoadIgnoreFile(rel); err != nil {
+				t.Error(err)
+			}
+			m.Match(rel+"/f.tmp", false)
+		}()
+	}
+	wg.Wait()
+}
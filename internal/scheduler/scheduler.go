@@ -0,0 +1,166 @@
+// Package scheduler runs a set of named, independently cron-scheduled backup
+// jobs and can reconcile that set against a freshly reloaded config without
+// disturbing jobs that didn't change, so a SIGHUP or config edit never drops
+// a job that's mid-run.
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"gorsyncv2/internal/config"
+)
+
+// RunStatus is one job's row in the "gorsync jobs" table.
+type RunStatus struct {
+	Name     string    `json:"name"`
+	Schedule string    `json:"schedule"`
+	NextRun  time.Time `json:"next_run"`
+	LastRun  time.Time `json:"last_run,omitempty"`
+	LastErr  string    `json:"last_error,omitempty"`
+}
+
+// RunFunc performs one job's backup. It is called from the cron goroutine,
+// so it must not panic and should return promptly relative to the job's
+// schedule.
+type RunFunc func(config.Job) error
+
+// Scheduler holds every currently-scheduled job and mirrors their status to
+// statusPath after every reconcile and every run, so "gorsync jobs" can read
+// it without talking to the running process directly.
+type Scheduler struct {
+	mu         sync.Mutex
+	cron       *cron.Cron
+	run        RunFunc
+	statusPath string
+
+	entries map[string]cron.EntryID
+	jobs    map[string]config.Job
+	status  map[string]RunStatus
+}
+
+// New creates a Scheduler that invokes run for each due job and persists
+// status to statusPath. Call Reconcile at least once before Start.
+func New(run RunFunc, statusPath string) *Scheduler {
+	return &Scheduler{
+		cron:       cron.New(),
+		run:        run,
+		statusPath: statusPath,
+		entries:    make(map[string]cron.EntryID),
+		jobs:       make(map[string]config.Job),
+		status:     make(map[string]RunStatus),
+	}
+}
+
+// Start begins running scheduled jobs in the background.
+func (s *Scheduler) Start() { s.cron.Start() }
+
+// Stop waits for any in-flight job to finish, then stops the scheduler.
+func (s *Scheduler) Stop() { <-s.cron.Stop().Done() }
+
+// Reconcile diffs jobs against the currently scheduled set by name: jobs no
+// longer present are removed, unchanged jobs are left running undisturbed,
+// and new or changed jobs are (re-)scheduled.
+func (s *Scheduler) Reconcile(jobs []config.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		seen[job.Name] = true
+
+		if old, ok := s.jobs[job.Name]; ok && reflect.DeepEqual(old, job) {
+			continue
+		}
+		if id, ok := s.entries[job.Name]; ok {
+			s.cron.Remove(id)
+		}
+
+		job := job
+		id, err := s.cron.AddFunc(job.Schedule, func() { s.runJob(job) })
+		if err != nil {
+			return fmt.Errorf("job %q: invalid schedule %q: %w", job.Name, job.Schedule, err)
+		}
+
+		s.entries[job.Name] = id
+		s.jobs[job.Name] = job
+		s.status[job.Name] = RunStatus{Name: job.Name, Schedule: job.Schedule, NextRun: s.cron.Entry(id).Next}
+	}
+
+	for name, id := range s.entries {
+		if seen[name] {
+			continue
+		}
+		s.cron.Remove(id)
+		delete(s.entries, name)
+		delete(s.jobs, name)
+		delete(s.status, name)
+	}
+
+	return s.writeStatusLocked()
+}
+
+func (s *Scheduler) runJob(job config.Job) {
+	err := s.run(job)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[job.Name]; !ok {
+		// Reconcile removed this job while the run was in flight; don't
+		// resurrect a status row for a job that no longer exists.
+		return
+	}
+
+	st := s.status[job.Name]
+	st.LastRun = time.Now()
+	if err != nil {
+		st.LastErr = err.Error()
+	} else {
+		st.LastErr = ""
+	}
+	if id, ok := s.entries[job.Name]; ok {
+		st.NextRun = s.cron.Entry(id).Next
+	}
+	s.status[job.Name] = st
+	s.writeStatusLocked()
+}
+
+func (s *Scheduler) writeStatusLocked() error {
+	if s.statusPath == "" {
+		return nil
+	}
+
+	rows := make([]RunStatus, 0, len(s.status))
+	for _, st := range s.status {
+		rows = append(rows, st)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+
+	data, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.statusPath, data, 0o644)
+}
+
+// ReadStatus loads the status rows written by a running Scheduler for
+// statusPath, for "gorsync jobs" to display.
+func ReadStatus(statusPath string) ([]RunStatus, error) {
+	data, err := os.ReadFile(statusPath)
+	if err != nil {
+		return nil, err
+	}
+	var rows []RunStatus
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,110 @@
+package snapshot
+
+import (
+	"bufio"
+	"io"
+)
+
+const (
+	windowSize = 64
+
+	// MinChunkSize and MaxChunkSize bound how small or large a single
+	// content-defined chunk may be, regardless of what the rolling hash says.
+	MinChunkSize = 512 * 1024
+	MaxChunkSize = 8 * 1024 * 1024
+
+	// avgChunkBits controls the average chunk size: a cut point is declared
+	// whenever the low avgChunkBits of the rolling hash are all zero, which
+	// happens on average every 2^avgChunkBits bytes.
+	avgChunkBits = 20
+	chunkMask    = uint64(1)<<avgChunkBits - 1
+)
+
+// chunkTable maps each possible input byte to a pseudo-random 64-bit value
+// used by the rolling hash below.
+var chunkTable = buildChunkTable()
+
+func buildChunkTable() [256]uint64 {
+	var t [256]uint64
+	h := uint64(0x9e3779b97f4a7c15)
+	for i := range t {
+		h ^= h >> 33
+		h *= 0xff51afd7ed558ccd
+		h ^= h >> 33
+		h *= 0xc4ceb9fe1a85ec53
+		h ^= h >> 33
+		t[i] = h
+	}
+	return t
+}
+
+func rol64(x uint64, k uint) uint64 {
+	k %= 64
+	if k == 0 {
+		return x
+	}
+	return (x << k) | (x >> (64 - k))
+}
+
+// Chunker splits a byte stream into variable-sized, content-defined chunks
+// using a rolling hash over a sliding window (a simplified Rabin-style
+// fingerprint): a cut point falls wherever the low bits of the hash match a
+// fixed mask. Because the hash only depends on the last windowSize bytes,
+// an edit in the middle of a file perturbs only the chunks touching it —
+// everything before and after still cuts at the same byte offsets, which is
+// what lets unchanged regions dedupe across snapshots.
+type Chunker struct {
+	r   *bufio.Reader
+	eof bool
+}
+
+// NewChunker wraps r so that repeated calls to Next produce its
+// content-defined chunks in order.
+func NewChunker(r io.Reader) *Chunker {
+	return &Chunker{r: bufio.NewReaderSize(r, 1<<20)}
+}
+
+// Next returns the bytes of the next chunk. It returns io.EOF (with no
+// bytes) once the stream is exhausted.
+func (c *Chunker) Next() ([]byte, error) {
+	if c.eof {
+		return nil, io.EOF
+	}
+
+	var window [windowSize]byte
+	var windowPos, filled int
+	var hash uint64
+	chunk := make([]byte, 0, 1<<20)
+
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				c.eof = true
+				if len(chunk) == 0 {
+					return nil, io.EOF
+				}
+				return chunk, nil
+			}
+			return nil, err
+		}
+		chunk = append(chunk, b)
+
+		if filled == windowSize {
+			out := window[windowPos]
+			hash = rol64(hash, 1) ^ chunkTable[b] ^ rol64(chunkTable[out], windowSize)
+		} else {
+			hash = rol64(hash, 1) ^ chunkTable[b]
+			filled++
+		}
+		window[windowPos] = b
+		windowPos = (windowPos + 1) % windowSize
+
+		if len(chunk) >= MaxChunkSize {
+			return chunk, nil
+		}
+		if len(chunk) >= MinChunkSize && filled == windowSize && hash&chunkMask == 0 {
+			return chunk, nil
+		}
+	}
+}
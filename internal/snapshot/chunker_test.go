@@ -0,0 +1,112 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+)
+
+// reassembled checks that chunking and rejoining data round-trips exactly,
+// and returns the chunks for callers that also want to inspect sizes.
+func reassembled(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+
+	c := NewChunker(bytes.NewReader(data))
+	var chunks [][]byte
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		chunks = append(chunks, append([]byte(nil), chunk...))
+	}
+
+	var got []byte
+	for _, chunk := range chunks {
+		got = append(got, chunk...)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("reassembled data does not match input: got %d bytes, want %d", len(got), len(data))
+	}
+	return chunks
+}
+
+func TestChunkerEmptyInput(t *testing.T) {
+	c := NewChunker(bytes.NewReader(nil))
+	if _, err := c.Next(); err != io.EOF {
+		t.Fatalf("Next on empty input: got err %v, want io.EOF", err)
+	}
+}
+
+func TestChunkerSmallInputIsOneChunk(t *testing.T) {
+	data := bytes.Repeat([]byte{'x'}, MinChunkSize/2)
+	chunks := reassembled(t, data)
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks for input smaller than MinChunkSize, want 1", len(chunks))
+	}
+}
+
+func TestChunkerRespectsMinAndMaxChunkSize(t *testing.T) {
+	data := make([]byte, 6*MaxChunkSize)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+	chunks := reassembled(t, data)
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks for %d bytes of random data, want at least 2", len(chunks), len(data))
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > MaxChunkSize {
+			t.Fatalf("chunk %d is %d bytes, exceeds MaxChunkSize %d", i, len(chunk), MaxChunkSize)
+		}
+		// The final chunk is whatever is left over and may be smaller
+		// than MinChunkSize; every other chunk must respect the floor.
+		if i < len(chunks)-1 && len(chunk) < MinChunkSize {
+			t.Fatalf("non-final chunk %d is %d bytes, under MinChunkSize %d", i, len(chunk), MinChunkSize)
+		}
+	}
+}
+
+// TestChunkerCutPointsAreContentDefined is the property that makes dedup
+// work: inserting a byte in the middle of the input shifts everything after
+// it, but the chunk boundaries before the insertion point are unaffected.
+func TestChunkerCutPointsAreContentDefined(t *testing.T) {
+	data := make([]byte, 4*MaxChunkSize)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+
+	original := reassembled(t, data)
+	if len(original) < 3 {
+		t.Fatalf("need at least 3 chunks for this test, got %d", len(original))
+	}
+
+	// Insert a single byte well inside the first chunk's data so the edit
+	// doesn't itself land on a cut point.
+	insertAt := len(original[0]) / 2
+	edited := make([]byte, 0, len(data)+1)
+	edited = append(edited, data[:insertAt]...)
+	edited = append(edited, 0xAB)
+	edited = append(edited, data[insertAt:]...)
+
+	modified := reassembled(t, edited)
+
+	// Every chunk after the one containing the edit should reappear
+	// byte-for-byte in the edited stream.
+	var matched int
+	for _, chunk := range original[1:] {
+		for _, other := range modified {
+			if bytes.Equal(chunk, other) {
+				matched++
+				break
+			}
+		}
+	}
+	if matched == 0 {
+		t.Fatalf("no unchanged chunks survived a single-byte insertion; content-defined cut points are not working")
+	}
+}
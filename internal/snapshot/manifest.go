@@ -0,0 +1,86 @@
+package snapshot
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileManifest records how a single file is reconstructed from chunks.
+type FileManifest struct {
+	Path    string      `json:"path"`
+	Mode    os.FileMode `json:"mode"`
+	ModTime time.Time   `json:"mtime"`
+	Chunks  []string    `json:"chunks"`
+}
+
+// chunkFile splits path into content-defined chunks, writes each into store,
+// and returns the resulting manifest. Path is recorded as given; callers
+// that want a repository-relative path should rewrite it afterwards.
+func chunkFile(store *Store, path string) (*FileManifest, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	chunker := NewChunker(f)
+	var hashes []string
+	for {
+		chunk, err := chunker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		hash, err := store.Put(chunk)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return &FileManifest{
+		Path:    path,
+		Mode:    info.Mode(),
+		ModTime: info.ModTime(),
+		Chunks:  hashes,
+	}, nil
+}
+
+// restoreFile rehydrates manifest's chunks into dst, creating parent
+// directories as needed.
+func restoreFile(store *Store, manifest *FileManifest, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for _, hash := range manifest.Chunks {
+		chunk, err := store.Get(hash)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(chunk); err != nil {
+			return err
+		}
+	}
+
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	return os.Chmod(dst, manifest.Mode)
+}
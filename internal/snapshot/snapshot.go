@@ -0,0 +1,127 @@
+// Package snapshot implements a deduplicated, content-addressed backup
+// repository: files are split into content-defined chunks, each chunk is
+// stored once under its hash, and a snapshot is just an index of which
+// chunks reconstitute which files. Repeated snapshots of slowly-changing
+// data only write the chunks that actually changed.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Index is the top-level record of a single snapshot run: when it was taken,
+// the source root it was taken from, and the manifests of every file it
+// contains.
+type Index struct {
+	Time  time.Time       `json:"time"`
+	Root  string          `json:"root"`
+	Files []*FileManifest `json:"files"`
+}
+
+// Create walks src, chunking and deduplicating every non-directory entry
+// (symlinks are followed) into the
+// content-addressed store rooted at backupDir, then writes a timestamped
+// snapshot index under <backupDir>/snapshots. It returns the new snapshot's
+// id.
+func Create(src, backupDir string) (string, error) {
+	store := NewStore(backupDir)
+	index := &Index{Root: src}
+
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		manifest, err := chunkFile(store, path)
+		if err != nil {
+			return fmt.Errorf("chunking %s: %w", path, err)
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		manifest.Path = relPath
+
+		index.Files = append(index.Files, manifest)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return writeIndex(backupDir, index)
+}
+
+func writeIndex(backupDir string, index *Index) (string, error) {
+	index.Time = time.Now()
+	id := index.Time.UTC().Format("20060102T150405Z")
+
+	dir := filepath.Join(backupDir, "snapshots")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, id+".json"), data, 0o644); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// List returns the ids of every snapshot stored under backupDir, sorted
+// (and therefore also chronological, since ids are timestamps).
+func List(backupDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(backupDir, "snapshots"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Restore rehydrates the snapshot identified by id into dst.
+func Restore(backupDir, id, dst string) error {
+	data, err := os.ReadFile(filepath.Join(backupDir, "snapshots", id+".json"))
+	if err != nil {
+		return err
+	}
+
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return err
+	}
+
+	store := NewStore(backupDir)
+	for _, manifest := range index.Files {
+		if err := restoreFile(store, manifest, filepath.Join(dst, manifest.Path)); err != nil {
+			return fmt.Errorf("restoring %s: %w", manifest.Path, err)
+		}
+	}
+	return nil
+}
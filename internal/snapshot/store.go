@@ -0,0 +1,55 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+)
+
+// Store is a content-addressed blob store rooted at <backupDir>/data, keyed
+// by the SHA-256 hash of each chunk's contents and sharded into two-character
+// prefix directories so no single directory accumulates too many entries.
+type Store struct {
+	root string
+}
+
+// NewStore opens the chunk store beneath backupDir.
+func NewStore(backupDir string) *Store {
+	return &Store{root: filepath.Join(backupDir, "data")}
+}
+
+// Put hashes chunk and writes it to the store if it isn't already present,
+// returning its hex-encoded hash either way.
+func (s *Store) Put(chunk []byte) (string, error) {
+	sum := sha256.Sum256(chunk)
+	hash := hex.EncodeToString(sum[:])
+
+	path := s.path(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	// Write to a temp file first and rename into place so a crash mid-write
+	// never leaves a corrupt chunk under its final, content-addressed name.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, chunk, 0o644); err != nil {
+		return "", err
+	}
+	return hash, os.Rename(tmp, path)
+}
+
+// Get reads back a chunk previously stored under hash.
+func (s *Store) Get(hash string) ([]byte, error) {
+	return os.ReadFile(s.path(hash))
+}
+
+func (s *Store) path(hash string) string {
+	return filepath.Join(s.root, hash[:2], hash)
+}